@@ -1,8 +1,11 @@
 package migrate
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"fmt"
+	"text/template"
 	"time"
 
 	"github.com/mdobak/go-xerrors"
@@ -22,21 +25,145 @@ type sqldbTX interface {
 	Rollback() error
 }
 
+// Placeholder selects the bind-parameter syntax used when rendering the
+// bookkeeping queries, overriding whatever the configured Dialect would
+// otherwise use.
+type Placeholder uint8
+
 const (
-	selectMigrationsSQL = `SELECT * FROM migrations ORDER BY "version" ASC`
-	insertMigrationSQL  = `INSERT INTO migrations ("version", "timestamp") VALUES ($1, $2)`
-	deleteMigrationSQL  = `DELETE FROM migrations WHERE "version" = $1`
-	createTableSQL      = `
-	  CREATE TABLE IF NOT EXISTS migrations (
-		"version" bigint NOT NULL,
-		"timestamp" timestamp NOT NULL,
-	  PRIMARY KEY ("version")
-	)`
+	// PlaceholderDollar renders "$1", "$2", ... placeholders, used by
+	// PostgreSQL.
+	PlaceholderDollar Placeholder = iota
+	// PlaceholderQuestion renders "?" placeholders, used by MySQL and
+	// SQLite.
+	PlaceholderQuestion
+)
+
+const defaultTableName = "migrations"
+
+var (
+	selectMigrationsSQLTpl = template.Must(template.New("select").Parse(
+		`SELECT * FROM {{.Table}} ORDER BY {{.Col "version"}} ASC`))
+	insertMigrationSQLTpl = template.Must(template.New("insert").Parse(
+		`INSERT INTO {{.Table}} ({{.Col "version"}}, {{.Col "timestamp"}}) VALUES ({{.Placeholder 1}}, {{.Placeholder 2}})`))
+	deleteMigrationSQLTpl = template.Must(template.New("delete").Parse(
+		`DELETE FROM {{.Table}} WHERE {{.Col "version"}} = {{.Placeholder 1}}`))
+	createTableSQLTpl = template.Must(template.New("create").Parse(`
+	  CREATE TABLE IF NOT EXISTS {{.Table}} (
+		{{.Col "version"}} bigint NOT NULL,
+		{{.Col "timestamp"}} {{.TimestampType}} NOT NULL,
+	  PRIMARY KEY ({{.Col "version"}})
+	)`))
 )
 
+// sqlTemplateData is passed to the templates used to render the bookkeeping
+// queries.
+type sqlTemplateData struct {
+	Schema    string
+	TableName string
+	dialect   Dialect
+}
+
+// Table renders the, optionally schema-qualified, bookkeeping table name.
+func (d sqlTemplateData) Table() string {
+	if d.Schema != "" {
+		return d.dialect.Quote(d.Schema) + "." + d.dialect.Quote(d.TableName)
+	}
+	return d.dialect.Quote(d.TableName)
+}
+
+// Col renders a quoted column name.
+func (d sqlTemplateData) Col(name string) string {
+	return d.dialect.Quote(name)
+}
+
+// Placeholder renders the nth bind-parameter placeholder.
+func (d sqlTemplateData) Placeholder(n int) string {
+	return d.dialect.Placeholder(n)
+}
+
+// TimestampType renders the column type used for the "timestamp" column.
+func (d sqlTemplateData) TimestampType() string {
+	return d.dialect.TimestampType()
+}
+
+func renderSQL(tpl *template.Template, data sqlTemplateData) string {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		// The templates above are static and data is always of the expected
+		// shape, so this can only happen if a template is broken.
+		panic(xerrors.New("migrate: invalid sql template", err))
+	}
+	return buf.String()
+}
+
+type sqlDatabaseOptions struct {
+	tableName           string
+	schema              string
+	disableCreateTable  bool
+	dialect             Dialect
+	placeholderOverride *Placeholder
+}
+
+// SQLDatabaseOption configures a SQLDatabase created with NewSQLDatabase.
+type SQLDatabaseOption func(*sqlDatabaseOptions)
+
+// WithTableName overrides the name of the table used to keep track of
+// applied migrations. Defaults to "migrations".
+func WithTableName(name string) SQLDatabaseOption {
+	return func(o *sqlDatabaseOptions) { o.tableName = name }
+}
+
+// WithSchema qualifies the bookkeeping table with the given schema.
+func WithSchema(schema string) SQLDatabaseOption {
+	return func(o *sqlDatabaseOptions) { o.schema = schema }
+}
+
+// WithDisableCreateTable prevents SQLDatabase from issuing a
+// "CREATE TABLE IF NOT EXISTS" for the bookkeeping table. Use this when the
+// table is already managed by other tooling.
+func WithDisableCreateTable() SQLDatabaseOption {
+	return func(o *sqlDatabaseOptions) { o.disableCreateTable = true }
+}
+
+// WithPlaceholder overrides the bind-parameter syntax used in the
+// bookkeeping queries, regardless of the configured Dialect.
+func WithPlaceholder(p Placeholder) SQLDatabaseOption {
+	return func(o *sqlDatabaseOptions) { o.placeholderOverride = &p }
+}
+
+// WithDialect selects the SQL dialect used to render the bookkeeping
+// queries, see Postgres, MySQL and SQLite. If not given, NewSQLDatabase
+// tries to detect the dialect from the driver of sqldb, falling back to
+// Postgres.
+func WithDialect(d Dialect) SQLDatabaseOption {
+	return func(o *sqlDatabaseOptions) { o.dialect = d }
+}
+
+// placeholderOverrideDialect wraps a Dialect to force a specific
+// Placeholder style, as requested through WithPlaceholder.
+type placeholderOverrideDialect struct {
+	Dialect
+	placeholder Placeholder
+}
+
+func (d placeholderOverrideDialect) Placeholder(n int) string {
+	if d.placeholder == PlaceholderQuestion {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
 type SQLDatabase struct {
 	sqldb  sqldb
 	inited bool
+
+	disableCreateTable bool
+
+	selectMigrationsSQL string
+	insertMigrationSQL  string
+	deleteMigrationSQL  string
+	createTableSQL      string
 }
 
 type migration struct {
@@ -44,20 +171,68 @@ type migration struct {
 	Timestamp time.Time `db:"timestamp"`
 }
 
-func NewSQLDatabase(sqldb sqldb) *SQLDatabase {
-	return &SQLDatabase{sqldb: sqldb}
+func NewSQLDatabase(sqldb sqldb, opts ...SQLDatabaseOption) *SQLDatabase {
+	o := sqlDatabaseOptions{tableName: defaultTableName}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dialect := o.dialect
+	if dialect == nil {
+		dialect = detectDialect(sqldb)
+	}
+	if dialect == nil {
+		dialect = Postgres
+	}
+	if o.placeholderOverride != nil {
+		dialect = placeholderOverrideDialect{Dialect: dialect, placeholder: *o.placeholderOverride}
+	}
+	data := sqlTemplateData{Schema: o.schema, TableName: o.tableName, dialect: dialect}
+	return &SQLDatabase{
+		sqldb:               sqldb,
+		disableCreateTable:  o.disableCreateTable,
+		selectMigrationsSQL: renderSQL(selectMigrationsSQLTpl, data),
+		insertMigrationSQL:  renderSQL(insertMigrationSQLTpl, data),
+		deleteMigrationSQL:  renderSQL(deleteMigrationSQLTpl, data),
+		createTableSQL:      renderSQL(createTableSQLTpl, data),
+	}
 }
 
 func (s *SQLDatabase) List(ctx context.Context) ([]int, error) {
+	ms, err := s.listMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var vs []int
+	for _, m := range ms {
+		vs = append(vs, m.Version)
+	}
+	return vs, nil
+}
+
+// ListWithTimestamps returns the applied migrations together with the time
+// each of them was applied, satisfying TimestampedDatabase.
+func (s *SQLDatabase) ListWithTimestamps(ctx context.Context) ([]MigrationRecord, error) {
+	ms, err := s.listMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]MigrationRecord, len(ms))
+	for i, m := range ms {
+		records[i] = MigrationRecord{Version: m.Version, AppliedAt: m.Timestamp}
+	}
+	return records, nil
+}
+
+func (s *SQLDatabase) listMigrations(ctx context.Context) ([]*migration, error) {
 	if err := s.init(ctx); err != nil {
 		return nil, err
 	}
-	var ms []*migration
-	rows, err := s.sqldb.QueryContext(ctx, selectMigrationsSQL)
-	defer rows.Close()
+	rows, err := s.sqldb.QueryContext(ctx, s.selectMigrationsSQL)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	var ms []*migration
 	for rows.Next() {
 		m := &migration{}
 		if err := rows.Scan(&m.Version, &m.Timestamp); err != nil {
@@ -65,17 +240,23 @@ func (s *SQLDatabase) List(ctx context.Context) ([]int, error) {
 		}
 		ms = append(ms, m)
 	}
-	if err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	var vs []int
-	for _, m := range ms {
-		vs = append(vs, m.Version)
-	}
-	return vs, nil
+	return ms, nil
 }
 
 func (s *SQLDatabase) Migrate(ctx context.Context, actions []Action) error {
+	return s.MigrateWithHooks(ctx, actions, Hooks{})
+}
+
+// MigrateWithHooks is like Migrate, but runs hooks.BeforeMigration and
+// hooks.AfterMigration around each action's execution, inside the same
+// transaction as its bookkeeping row update. For actions with NoTransaction
+// set, the hooks and the action run outside any transaction and the
+// bookkeeping row is written in a separate one. It satisfies
+// HookAwareDatabase.
+func (s *SQLDatabase) MigrateWithHooks(ctx context.Context, actions []Action, hooks Hooks) error {
 	if err := s.init(ctx); err != nil {
 		return err
 	}
@@ -83,9 +264,9 @@ func (s *SQLDatabase) Migrate(ctx context.Context, actions []Action) error {
 		var err error
 		switch action.Direction {
 		case Up:
-			err = s.up(ctx, action)
+			err = s.up(ctx, action, hooks)
 		case Down:
-			err = s.down(ctx, action)
+			err = s.down(ctx, action, hooks)
 		}
 		if err != nil {
 			return err
@@ -94,38 +275,85 @@ func (s *SQLDatabase) Migrate(ctx context.Context, actions []Action) error {
 	return nil
 }
 
-func (s *SQLDatabase) up(ctx context.Context, action Action) error {
-	return transaction(ctx, s.sqldb, func(db sqldb) error {
-		_, err := db.ExecContext(ctx, action.Migration)
-		if err != nil {
+func (s *SQLDatabase) up(ctx context.Context, action Action, hooks Hooks) error {
+	if action.NoTransaction {
+		if err := runAction(ctx, s.sqldb, nil, action, hooks); err != nil {
 			return err
 		}
-		_, err = db.ExecContext(ctx, insertMigrationSQL, action.Version, time.Now())
+		return transaction(ctx, s.sqldb, func(db sqldb, tx *sql.Tx) error {
+			_, err := db.ExecContext(ctx, s.insertMigrationSQL, action.Version, time.Now())
+			return err
+		})
+	}
+	return transaction(ctx, s.sqldb, func(db sqldb, tx *sql.Tx) error {
+		if err := runAction(ctx, db, tx, action, hooks); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(ctx, s.insertMigrationSQL, action.Version, time.Now())
 		return err
 	})
 }
 
-func (s *SQLDatabase) down(ctx context.Context, action Action) error {
-	return transaction(ctx, s.sqldb, func(db sqldb) error {
-		_, err := db.ExecContext(ctx, action.Migration)
-		if err != nil {
+func (s *SQLDatabase) down(ctx context.Context, action Action, hooks Hooks) error {
+	if action.NoTransaction {
+		if err := runAction(ctx, s.sqldb, nil, action, hooks); err != nil {
+			return err
+		}
+		return transaction(ctx, s.sqldb, func(db sqldb, tx *sql.Tx) error {
+			_, err := db.ExecContext(ctx, s.deleteMigrationSQL, action.Version)
+			return err
+		})
+	}
+	return transaction(ctx, s.sqldb, func(db sqldb, tx *sql.Tx) error {
+		if err := runAction(ctx, db, tx, action, hooks); err != nil {
 			return err
 		}
-		_, err = db.ExecContext(ctx, deleteMigrationSQL, action.Version)
+		_, err := db.ExecContext(ctx, s.deleteMigrationSQL, action.Version)
 		return err
 	})
 }
 
+// runAction executes action, calling hooks.BeforeMigration and
+// hooks.AfterMigration around it.
+func runAction(ctx context.Context, db sqldb, tx *sql.Tx, action Action, hooks Hooks) error {
+	if hooks.BeforeMigration != nil {
+		if err := hooks.BeforeMigration(ctx, action); err != nil {
+			return err
+		}
+	}
+	err := execAction(ctx, db, tx, action)
+	if hooks.AfterMigration != nil {
+		hooks.AfterMigration(ctx, action, err)
+	}
+	return err
+}
+
+// execAction runs the migration carried by action, preferring action.Exec
+// when set over the raw SQL in action.Migration.
+func execAction(ctx context.Context, db sqldb, tx *sql.Tx, action Action) error {
+	if action.Exec != nil {
+		if tx == nil {
+			return xerrors.New("go migration requires a database that supports transactions")
+		}
+		return action.Exec(ctx, tx)
+	}
+	_, err := db.ExecContext(ctx, action.Migration)
+	return err
+}
+
 func (s *SQLDatabase) init(ctx context.Context) error {
 	if s.inited {
 		return nil
 	}
 	s.inited = true
-	_, err := s.sqldb.ExecContext(ctx, createTableSQL)
+	if s.disableCreateTable {
+		return nil
+	}
+	_, err := s.sqldb.ExecContext(ctx, s.createTableSQL)
 	return err
 }
 
-func transaction(ctx context.Context, sqldb sqldb, fn func(db sqldb) error) (err error) {
+func transaction(ctx context.Context, sqldb sqldb, fn func(db sqldb, tx *sql.Tx) error) (err error) {
 	if sqldb, ok := sqldb.(sqldbBeginTx); ok {
 		tx, err := sqldb.BeginTx(ctx, nil)
 		if err != nil {
@@ -142,11 +370,11 @@ func transaction(ctx context.Context, sqldb sqldb, fn func(db sqldb) error) (err
 				}
 			}
 		}()
-		err = fn(tx)
+		err = fn(tx, tx)
 		if err != nil {
 			return err
 		}
 		return tx.Commit()
 	}
-	return fn(sqldb)
+	return fn(sqldb, nil)
 }
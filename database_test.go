@@ -0,0 +1,162 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type databaseExecMock struct {
+	createTableCalls int
+}
+
+func (d *databaseExecMock) QueryContext(_ context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (d *databaseExecMock) ExecContext(_ context.Context, _ string, _ ...interface{}) (sql.Result, error) {
+	d.createTableCalls++
+	return nil, nil
+}
+
+func TestNewSQLDatabase_WithTableNameAndSchema(t *testing.T) {
+	s := NewSQLDatabase(&databaseExecMock{}, WithTableName("schema_migrations"), WithSchema("app"))
+	require.Contains(t, s.selectMigrationsSQL, `"app"."schema_migrations"`)
+}
+
+func TestSQLDatabase_WithDisableCreateTable(t *testing.T) {
+	ctx := context.Background()
+	exec := &databaseExecMock{}
+	s := NewSQLDatabase(exec, WithDisableCreateTable())
+
+	require.NoError(t, s.init(ctx))
+	require.Equal(t, 0, exec.createTableCalls)
+}
+
+func TestNewSQLDatabase_DefaultsToPostgres(t *testing.T) {
+	s := NewSQLDatabase(&databaseExecMock{})
+	require.Contains(t, s.createTableSQL, `"migrations"`)
+	require.Contains(t, s.createTableSQL, "timestamp")
+	require.Contains(t, s.insertMigrationSQL, "$1")
+}
+
+func TestNewSQLDatabase_WithDialect_MySQL(t *testing.T) {
+	s := NewSQLDatabase(&databaseExecMock{}, WithDialect(MySQL))
+	require.Contains(t, s.createTableSQL, "`migrations`")
+	require.Contains(t, s.createTableSQL, "DATETIME")
+	require.Contains(t, s.insertMigrationSQL, "?")
+}
+
+func TestNewSQLDatabase_WithPlaceholder_OverridesDialect(t *testing.T) {
+	s := NewSQLDatabase(&databaseExecMock{}, WithDialect(Postgres), WithPlaceholder(PlaceholderQuestion))
+	require.Contains(t, s.insertMigrationSQL, "?")
+	require.Contains(t, s.insertMigrationSQL, `"version"`)
+}
+
+func TestNewSQLDatabase_DetectsDialectFromDriver(t *testing.T) {
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+	require.Contains(t, s.createTableSQL, "DATETIME")
+}
+
+func TestSQLDatabase_MigrateWithHooks_FiresAroundAction(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+
+	var before, after []Action
+	hooks := Hooks{
+		BeforeMigration: func(_ context.Context, a Action) error {
+			before = append(before, a)
+			return nil
+		},
+		AfterMigration: func(_ context.Context, a Action, err error) {
+			after = append(after, a)
+			require.NoError(t, err)
+		},
+	}
+
+	action := Action{Version: 1, Migration: `CREATE TABLE t (id INTEGER PRIMARY KEY)`, Direction: Up}
+	err := s.MigrateWithHooks(ctx, []Action{action}, hooks)
+	require.NoError(t, err)
+	require.Equal(t, []Action{action}, before)
+	require.Equal(t, []Action{action}, after)
+
+	versions, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, versions)
+}
+
+func TestSQLDatabase_MigrateWithHooks_BeforeMigrationErrorSkipsAction(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+
+	hooks := Hooks{
+		BeforeMigration: func(_ context.Context, _ Action) error {
+			return errors.New("blocked")
+		},
+	}
+
+	action := Action{Version: 1, Migration: `CREATE TABLE t (id INTEGER PRIMARY KEY)`, Direction: Up}
+	err := s.MigrateWithHooks(ctx, []Action{action}, hooks)
+	require.Error(t, err)
+
+	versions, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestSQLDatabase_NoTransaction_UpAndDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+
+	up := Action{
+		Version:       1,
+		Migration:     `CREATE TABLE t (id INTEGER PRIMARY KEY)`,
+		NoTransaction: true,
+		Direction:     Up,
+	}
+	err := s.Migrate(ctx, []Action{up})
+	require.NoError(t, err)
+
+	versions, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, versions)
+
+	down := Action{
+		Version:       1,
+		Migration:     `DROP TABLE t`,
+		NoTransaction: true,
+		Direction:     Down,
+	}
+	err = s.Migrate(ctx, []Action{down})
+	require.NoError(t, err)
+
+	versions, err = s.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestSQLDatabase_NoTransaction_FailedMigrationSkipsBookkeeping(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+
+	up := Action{
+		Version:       1,
+		Migration:     `NOT VALID SQL`,
+		NoTransaction: true,
+		Direction:     Up,
+	}
+	err := s.Migrate(ctx, []Action{up})
+	require.Error(t, err)
+
+	versions, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
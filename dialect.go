@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect abstracts the small set of SQL differences between database
+// engines that SQLDatabase needs to know about to manage its bookkeeping
+// table: the bind-parameter syntax, the column type used to store the
+// applied-at timestamp, and identifier quoting.
+type Dialect interface {
+	// Placeholder renders the nth (1-indexed) bind-parameter placeholder.
+	Placeholder(n int) string
+	// TimestampType returns the column type used for the "timestamp"
+	// column of the bookkeeping table.
+	TimestampType() string
+	// Quote renders a quoted identifier, e.g. a table or column name.
+	Quote(identifier string) string
+}
+
+// PostgresDialect is a Dialect for PostgreSQL.
+type PostgresDialect struct{}
+
+func (PostgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+func (PostgresDialect) TimestampType() string    { return "timestamp" }
+func (PostgresDialect) Quote(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+// MySQLDialect is a Dialect for MySQL and MariaDB.
+type MySQLDialect struct{}
+
+func (MySQLDialect) Placeholder(int) string { return "?" }
+func (MySQLDialect) TimestampType() string  { return "DATETIME" }
+func (MySQLDialect) Quote(identifier string) string {
+	return "`" + identifier + "`"
+}
+
+// SQLiteDialect is a Dialect for SQLite.
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Placeholder(int) string { return "?" }
+func (SQLiteDialect) TimestampType() string  { return "DATETIME" }
+func (SQLiteDialect) Quote(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+// Predefined dialects, for use with WithDialect.
+var (
+	Postgres Dialect = PostgresDialect{}
+	MySQL    Dialect = MySQLDialect{}
+	SQLite   Dialect = SQLiteDialect{}
+)
+
+// detectDialect makes a best-effort guess of the Dialect to use based on the
+// driver name of db, returning nil if it cannot be determined.
+func detectDialect(db sqldb) Dialect {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return nil
+	}
+	name := strings.ToLower(fmt.Sprintf("%T", sqlDB.Driver()))
+	switch {
+	case strings.Contains(name, "mysql"):
+		return MySQL
+	case strings.Contains(name, "sqlite"):
+		return SQLite
+	case strings.Contains(name, "postgres"), strings.Contains(name, "pgx"), strings.Contains(name, "pq."):
+		return Postgres
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCodeProvider_List(t *testing.T) {
+	ctx := context.Background()
+
+	up1 := func(ctx context.Context, tx *sql.Tx) error { return nil }
+	down1 := func(ctx context.Context, tx *sql.Tx) error { return nil }
+	up2 := func(ctx context.Context, tx *sql.Tx) error { return nil }
+
+	p := NewCodeProvider()
+	p.Register(2, up2, nil)
+	p.Register(1, up1, down1)
+
+	ms, err := p.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+
+	// List returns migrations ordered by version, regardless of
+	// registration order.
+	require.Equal(t, 1, ms[0].Version(ctx))
+	require.Equal(t, 2, ms[1].Version(ctx))
+
+	upFn, err := ms[0].(FuncMigration).UpFunc(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, upFn)
+	downFn, err := ms[0].(FuncMigration).DownFunc(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, downFn)
+
+	downFn, err = ms[1].(FuncMigration).DownFunc(ctx)
+	require.NoError(t, err)
+	require.Nil(t, downFn)
+}
+
+func TestMigrate_Plan_Down_GoMigrationWithoutDownFn(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewCodeProvider()
+	p.Register(1, func(ctx context.Context, tx *sql.Tx) error { return nil }, nil)
+	d := &databaseMock{}
+	m := New(p, d)
+
+	d.On("List").Return([]int{1}, nil)
+	_, err := m.Plan(ctx, 0)
+	require.Error(t, err)
+}
+
+func TestSQLDatabase_GoMigration_RunsWithinTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s := NewSQLDatabase(db)
+
+	action := Action{
+		Version: 1,
+		Exec: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE t (id INTEGER PRIMARY KEY)`)
+			return err
+		},
+		Direction: Up,
+	}
+	err := s.Migrate(ctx, []Action{action})
+	require.NoError(t, err)
+
+	versions, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, versions)
+}
@@ -0,0 +1,32 @@
+package migrate
+
+import "context"
+
+// Hooks are lifecycle callbacks a Migrator invokes while preparing and
+// applying migrations. They are useful for structured logging, metrics,
+// tracing, or gating destructive migrations behind an interactive prompt.
+// All fields are optional.
+type Hooks struct {
+	// BeforePlan is called before the Migrator computes the list of
+	// migrations needed to reach the requested version.
+	BeforePlan func(ctx context.Context)
+	// BeforeMigration is called before a single Action is executed. If it
+	// returns an error, the Action is not executed and the error is
+	// returned from Migrate instead.
+	BeforeMigration func(ctx context.Context, action Action) error
+	// AfterMigration is called after a single Action has been executed,
+	// before its bookkeeping row is written, with the error the execution
+	// returned, if any.
+	AfterMigration func(ctx context.Context, action Action, err error)
+	// OnError is called whenever Migrate fails, with the error that caused
+	// the failure.
+	OnError func(ctx context.Context, err error)
+}
+
+// HookAwareDatabase is an optional interface a Database may implement to run
+// Hooks between a migration's execution and its bookkeeping row update,
+// inside the same transaction. SQLDatabase implements it; Migrator falls
+// back to Database.Migrate, without hooks, for databases that don't.
+type HookAwareDatabase interface {
+	MigrateWithHooks(ctx context.Context, actions []Action, hooks Hooks) error
+}
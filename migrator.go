@@ -1,7 +1,8 @@
-package go_migrate
+package migrate
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"sort"
 
@@ -38,19 +39,63 @@ type Migration interface {
 	Snapshot(ctx context.Context) (string, error)
 }
 
+// FuncMigration is an optional interface that a Migration may implement to
+// execute Go code instead of raw SQL. When a Migration implements this
+// interface, the returned functions take precedence over the SQL returned by
+// Up, Down and Snapshot and are run inside the same transaction as the
+// bookkeeping insert/delete.
+type FuncMigration interface {
+	UpFunc(ctx context.Context) (func(ctx context.Context, tx *sql.Tx) error, error)
+	DownFunc(ctx context.Context) (func(ctx context.Context, tx *sql.Tx) error, error)
+	SnapshotFunc(ctx context.Context) (func(ctx context.Context, tx *sql.Tx) error, error)
+}
+
+// MigrationOptions are per-migration settings returned by a
+// ConfigurableMigration.
+type MigrationOptions struct {
+	// NoTransaction, when set, tells the Database to run the migration
+	// outside of a transaction. This is required for statements that cannot
+	// run inside one, such as Postgres' CREATE INDEX CONCURRENTLY or MySQL's
+	// online schema changes. The bookkeeping row is still written, in its
+	// own short-lived transaction; a crash between the two is the user's
+	// problem to reconcile.
+	NoTransaction bool
+}
+
+// ConfigurableMigration is an optional interface a Migration may implement
+// to customize how it is executed.
+type ConfigurableMigration interface {
+	Options(ctx context.Context) (MigrationOptions, error)
+}
+
 type Action struct {
-	Version   int
-	Migration string
-	Direction Direction
+	Version       int
+	Migration     string
+	Exec          func(ctx context.Context, tx *sql.Tx) error
+	NoTransaction bool
+	Direction     Direction
 }
 
 type Migrator struct {
-	prov Provider
-	db   Database
+	prov  Provider
+	db    Database
+	hooks Hooks
 }
 
-func New(prov Provider, db Database) *Migrator {
-	return &Migrator{prov: prov, db: db}
+// MigratorOption configures a Migrator created with New.
+type MigratorOption func(*Migrator)
+
+// WithHooks attaches lifecycle Hooks to a Migrator.
+func WithHooks(h Hooks) MigratorOption {
+	return func(m *Migrator) { m.hooks = h }
+}
+
+func New(prov Provider, db Database, opts ...MigratorOption) *Migrator {
+	m := &Migrator{prov: prov, db: db}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // LatestVersion returns latest available migration version.
@@ -89,15 +134,36 @@ func (m *Migrator) CurrentVersion(ctx context.Context) (int, error) {
 func (m *Migrator) Migrate(ctx context.Context, version int) error {
 	actions, err := m.plan(ctx, version)
 	if err != nil {
-		return xerrors.New(ErrMigrator, "unable to prepare migration plan", err)
+		err = xerrors.New(ErrMigrator, "unable to prepare migration plan", err)
+		m.onError(ctx, err)
+		return err
+	}
+	if hd, ok := m.db.(HookAwareDatabase); ok {
+		err = hd.MigrateWithHooks(ctx, actions, m.hooks)
+	} else {
+		err = m.db.Migrate(ctx, actions)
 	}
-	err = m.db.Migrate(ctx, actions)
 	if err != nil {
-		return xerrors.New(ErrMigrator, "unable to apply migrations", err)
+		err = xerrors.New(ErrMigrator, "unable to apply migrations", err)
+		m.onError(ctx, err)
+		return err
 	}
 	return nil
 }
 
+func (m *Migrator) onError(ctx context.Context, err error) {
+	if m.hooks.OnError != nil {
+		m.hooks.OnError(ctx, err)
+	}
+}
+
+func migrationOptions(ctx context.Context, pm Migration) (MigrationOptions, error) {
+	if cm, ok := pm.(ConfigurableMigration); ok {
+		return cm.Options(ctx)
+	}
+	return MigrationOptions{}, nil
+}
+
 // Plan returns a list migrations needed to reach the required version.
 // If version number is higher than the latest available version, then
 // the latest version is used.
@@ -110,6 +176,9 @@ func (m *Migrator) Plan(ctx context.Context, version int) ([]Action, error) {
 }
 
 func (m *Migrator) plan(ctx context.Context, version int) ([]Action, error) {
+	if m.hooks.BeforePlan != nil {
+		m.hooks.BeforePlan(ctx)
+	}
 	// Fetch the list of available migrations from the provider.
 	provList, err := m.prov.List(ctx)
 	if err != nil {
@@ -158,11 +227,30 @@ func (m *Migrator) plan(ctx context.Context, version int) ([]Action, error) {
 							err,
 						)
 					}
-					if len(snapshot) > 0 {
+					var snapshotFn func(ctx context.Context, tx *sql.Tx) error
+					if fm, ok := pm.(FuncMigration); ok {
+						snapshotFn, err = fm.SnapshotFunc(ctx)
+						if err != nil {
+							return nil, xerrors.New(
+								fmt.Sprintf("unable to load snapshot from migration %d", pm.Version(ctx)),
+								err,
+							)
+						}
+					}
+					opts, err := migrationOptions(ctx, pm)
+					if err != nil {
+						return nil, xerrors.New(
+							fmt.Sprintf("unable to load options for migration %d", pm.Version(ctx)),
+							err,
+						)
+					}
+					if len(snapshot) > 0 || snapshotFn != nil {
 						actions = []Action{{
-							Version:   pm.Version(ctx),
-							Migration: snapshot,
-							Direction: Up,
+							Version:       pm.Version(ctx),
+							Migration:     snapshot,
+							Exec:          snapshotFn,
+							NoTransaction: opts.NoTransaction,
+							Direction:     Up,
 						}}
 						continue
 					}
@@ -175,10 +263,29 @@ func (m *Migrator) plan(ctx context.Context, version int) ([]Action, error) {
 						err,
 					)
 				}
+				var upFn func(ctx context.Context, tx *sql.Tx) error
+				if fm, ok := pm.(FuncMigration); ok {
+					upFn, err = fm.UpFunc(ctx)
+					if err != nil {
+						return nil, xerrors.New(
+							fmt.Sprintf("unable to load %d up migration", pm.Version(ctx)),
+							err,
+						)
+					}
+				}
+				opts, err := migrationOptions(ctx, pm)
+				if err != nil {
+					return nil, xerrors.New(
+						fmt.Sprintf("unable to load options for migration %d", pm.Version(ctx)),
+						err,
+					)
+				}
 				actions = append(actions, Action{
-					Version:   pm.Version(ctx),
-					Migration: up,
-					Direction: Up,
+					Version:       pm.Version(ctx),
+					Migration:     up,
+					Exec:          upFn,
+					NoTransaction: opts.NoTransaction,
+					Direction:     Up,
 				})
 			} else {
 				// To prevent a migration from being applied when a migration
@@ -206,10 +313,34 @@ func (m *Migrator) plan(ctx context.Context, version int) ([]Action, error) {
 						err,
 					)
 				}
+				var downFn func(ctx context.Context, tx *sql.Tx) error
+				if fm, ok := provList[idx].(FuncMigration); ok {
+					downFn, err = fm.DownFunc(ctx)
+					if err != nil {
+						return nil, xerrors.New(
+							fmt.Sprintf("unable to load %d down migration", provList[idx].Version(ctx)),
+							err,
+						)
+					}
+					if downFn == nil {
+						return nil, xerrors.New(
+							fmt.Sprintf("migration %d cannot be reverted: no down migration registered", provList[idx].Version(ctx)),
+						)
+					}
+				}
+				opts, err := migrationOptions(ctx, provList[idx])
+				if err != nil {
+					return nil, xerrors.New(
+						fmt.Sprintf("unable to load options for migration %d", provList[idx].Version(ctx)),
+						err,
+					)
+				}
 				actions = append(actions, Action{
-					Version:   provList[idx].Version(ctx),
-					Migration: down,
-					Direction: Down,
+					Version:       provList[idx].Version(ctx),
+					Migration:     down,
+					Exec:          downFn,
+					NoTransaction: opts.NoTransaction,
+					Direction:     Down,
 				})
 			}
 		}
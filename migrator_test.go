@@ -2,6 +2,7 @@ package migrate
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -202,3 +203,25 @@ func TestMigrate_Plan_Up_SkipToSnapshot(t *testing.T) {
 		{Version: 5, Direction: Up, Migration: "up5"},
 	}, actions)
 }
+
+func TestMigrate_Migrate_FiresBeforePlanAndOnError(t *testing.T) {
+	ctx := context.Background()
+
+	p := &providerMock{}
+	d := &databaseMock{}
+	var beforePlanCalls int
+	var onErrorCalls []error
+	m := New(p, d, WithHooks(Hooks{
+		BeforePlan: func(_ context.Context) { beforePlanCalls++ },
+		OnError:    func(_ context.Context, err error) { onErrorCalls = append(onErrorCalls, err) },
+	}))
+
+	p.On("List").Return([]Migration{&testMigration{version: 1, up: "up1", down: "down1"}}, nil)
+	d.On("List").Return([]int{}, nil)
+	d.On("Migrate", mock.Anything).Return(errors.New("boom"))
+
+	err := m.Migrate(ctx, 1)
+	require.Error(t, err)
+	require.Equal(t, 1, beforePlanCalls)
+	require.Len(t, onErrorCalls, 1)
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/mdobak/go-xerrors"
 )
@@ -16,6 +17,7 @@ import (
 const sqlUpSeparator = "--UP--"
 const sqlDownSeparator = "--DOWN--"
 const sqlSnapshotSeparator = "--SNAPSHOT--"
+const sqlNoTransactionDirective = "--NOTRANSACTION--"
 
 type FS interface {
 	fs.FS
@@ -23,13 +25,44 @@ type FS interface {
 	fs.ReadFileFS
 }
 
+type filesystemProviderOptions struct {
+	templateData map[string]any
+	strict       bool
+}
+
+// FilesystemProviderOption configures a FilesystemProvider created with
+// NewFilesystemProvider.
+type FilesystemProviderOption func(*filesystemProviderOptions)
+
+// WithTemplateData renders the UP, DOWN and SNAPSHOT sections of every
+// migration file as Go text/template sources with the given data, after the
+// file has been split into those sections. This allows a single migration tree to adapt to, e.g.,
+// multi-tenant schemas or dialect differences without forking files.
+func WithTemplateData(data map[string]any) FilesystemProviderOption {
+	return func(o *filesystemProviderOptions) { o.templateData = data }
+}
+
+// WithStrictTemplates makes migration file templates fail instead of
+// rendering the literal text "<no value>" when they reference a key that is
+// not present in the template data.
+func WithStrictTemplates() FilesystemProviderOption {
+	return func(o *filesystemProviderOptions) { o.strict = true }
+}
+
 type FilesystemProvider struct {
 	fs   FS
 	path string
+
+	templateData map[string]any
+	strict       bool
 }
 
-func NewFilesystemProvider(fs FS, path string) *FilesystemProvider {
-	return &FilesystemProvider{fs: fs, path: path}
+func NewFilesystemProvider(fs FS, path string, opts ...FilesystemProviderOption) *FilesystemProvider {
+	o := filesystemProviderOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &FilesystemProvider{fs: fs, path: path, templateData: o.templateData, strict: o.strict}
 }
 
 func (p *FilesystemProvider) List(ctx context.Context) ([]Migration, error) {
@@ -47,7 +80,13 @@ func (p *FilesystemProvider) List(ctx context.Context) ([]Migration, error) {
 		if !ok {
 			return nil, xerrors.New("invalid file name")
 		}
-		ms = append(ms, &fileMigration{fs: p.fs, filepath: fp, version: v})
+		ms = append(ms, &fileMigration{
+			fs:           p.fs,
+			filepath:     fp,
+			version:      v,
+			templateData: p.templateData,
+			strict:       p.strict,
+		})
 	}
 	return ms, nil
 }
@@ -58,13 +97,22 @@ type fileMigration struct {
 	version  int
 	isRead   bool
 
+	templateData map[string]any
+	strict       bool
+
 	up, down, snapshot []byte
+	noTransaction      bool
 }
 
 func (m *fileMigration) Version(ctx context.Context) int {
 	return m.version
 }
 
+// Name returns the base name of the migration file.
+func (m *fileMigration) Name(ctx context.Context) string {
+	return path.Base(m.filepath)
+}
+
 func (m *fileMigration) Up(ctx context.Context) (string, error) {
 	if err := m.read(); err != nil {
 		return "", err
@@ -86,6 +134,15 @@ func (m *fileMigration) Snapshot(ctx context.Context) (string, error) {
 	return string(m.snapshot), nil
 }
 
+// Options returns the per-file directives recognized by parseFileContent,
+// satisfying ConfigurableMigration.
+func (m *fileMigration) Options(ctx context.Context) (MigrationOptions, error) {
+	if err := m.read(); err != nil {
+		return MigrationOptions{}, err
+	}
+	return MigrationOptions{NoTransaction: m.noTransaction}, nil
+}
+
 func (m *fileMigration) read() error {
 	if m.isRead {
 		return nil
@@ -95,12 +152,46 @@ func (m *fileMigration) read() error {
 		return err
 	}
 	defer f.Close()
-	m.up, m.down, m.snapshot = parseFileContent(f)
+	up, down, snapshot, noTransaction := parseFileContent(f)
+	if up, err = m.renderTemplate(up); err != nil {
+		return err
+	}
+	if down, err = m.renderTemplate(down); err != nil {
+		return err
+	}
+	if snapshot, err = m.renderTemplate(snapshot); err != nil {
+		return err
+	}
+	m.up, m.down, m.snapshot, m.noTransaction = up, down, snapshot, noTransaction
 	m.isRead = true
 	return nil
 }
 
-func parseFileContent(r io.Reader) ([]byte, []byte, []byte) {
+// renderTemplate renders src as a Go text/template source using the
+// migration's template data. It is a no-op for empty sections and, unless
+// WithTemplateData was passed to NewFilesystemProvider, a no-op for every
+// section, so migration files are only ever parsed as templates when the
+// caller opted in.
+func (m *fileMigration) renderTemplate(src []byte) ([]byte, error) {
+	if len(src) == 0 || m.templateData == nil {
+		return src, nil
+	}
+	t := template.New(m.filepath)
+	if m.strict {
+		t = t.Option("missingkey=error")
+	}
+	t, err := t.Parse(string(src))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, m.templateData); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseFileContent(r io.Reader) ([]byte, []byte, []byte, bool) {
 	const (
 		outside  = 0
 		up       = 1
@@ -109,6 +200,7 @@ func parseFileContent(r io.Reader) ([]byte, []byte, []byte) {
 	)
 	s := bufio.NewScanner(r)
 	var loc = outside
+	var noTransaction bool
 	var bUp, bDown, bSnapshot bytes.Buffer
 	for s.Scan() {
 		line := s.Bytes()
@@ -119,6 +211,8 @@ func parseFileContent(r io.Reader) ([]byte, []byte, []byte) {
 			loc = down
 		case bytes.Equal(line, []byte(sqlSnapshotSeparator)):
 			loc = snapshot
+		case bytes.Equal(line, []byte(sqlNoTransactionDirective)):
+			noTransaction = true
 		case loc == up:
 			bUp.Write(line)
 			bUp.WriteByte('\n')
@@ -130,7 +224,7 @@ func parseFileContent(r io.Reader) ([]byte, []byte, []byte) {
 			bSnapshot.WriteByte('\n')
 		}
 	}
-	return bytes.TrimSpace(bUp.Bytes()), bytes.TrimSpace(bDown.Bytes()), bytes.TrimSpace(bSnapshot.Bytes())
+	return bytes.TrimSpace(bUp.Bytes()), bytes.TrimSpace(bDown.Bytes()), bytes.TrimSpace(bSnapshot.Bytes()), noTransaction
 }
 
 func parseFileName(filepath string) (int, bool) {
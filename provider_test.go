@@ -3,6 +3,7 @@ package migrate
 import (
 	"context"
 	"embed"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -77,3 +78,31 @@ func TestFilesystemProvider_List(t *testing.T) {
 	require.Equal(t, sqlDown2[1:], down2)
 	require.Equal(t, sqlSnapshot2[1:], snapshot2)
 }
+
+func TestParseFileContent_NoTransaction(t *testing.T) {
+	up, down, _, noTransaction := parseFileContent(strings.NewReader(
+		"--NOTRANSACTION--\n--UP--\nCREATE INDEX CONCURRENTLY idx ON t (c);\n--DOWN--\nDROP INDEX idx;"))
+
+	require.True(t, noTransaction)
+	require.Equal(t, "CREATE INDEX CONCURRENTLY idx ON t (c);", string(up))
+	require.Equal(t, "DROP INDEX idx;", string(down))
+}
+
+func TestParseFileContent_NoTransaction_NotSet(t *testing.T) {
+	_, _, _, noTransaction := parseFileContent(strings.NewReader(
+		"--UP--\nCREATE TABLE t (c int);\n--DOWN--\nDROP TABLE t;"))
+
+	require.False(t, noTransaction)
+}
+
+func TestFileMigration_RenderTemplate(t *testing.T) {
+	m := &fileMigration{templateData: map[string]any{"Tenant": "acme"}}
+
+	up, err := m.renderTemplate([]byte(`CREATE SCHEMA {{ .Tenant }};`))
+	require.NoError(t, err)
+	require.Equal(t, `CREATE SCHEMA acme;`, string(up))
+
+	m.strict = true
+	_, err = m.renderTemplate([]byte(`CREATE SCHEMA {{ .Missing }};`))
+	require.Error(t, err)
+}
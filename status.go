@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mdobak/go-xerrors"
+)
+
+// MigrationRecord describes a migration as stored by a Database, along with
+// the time it was applied.
+type MigrationRecord struct {
+	Version   int
+	AppliedAt time.Time
+}
+
+// TimestampedDatabase is an optional interface a Database may implement to
+// report when each migration was applied. Status uses it when available
+// instead of Database.List, which only reports versions.
+type TimestampedDatabase interface {
+	ListWithTimestamps(ctx context.Context) ([]MigrationRecord, error)
+}
+
+// NamedMigration is an optional interface a Migration may implement to
+// describe itself with a human-readable name, e.g. its source file name.
+type NamedMigration interface {
+	Name(ctx context.Context) string
+}
+
+// MigrationState describes the relationship between a migration and what is
+// currently recorded in the database.
+type MigrationState uint8
+
+const (
+	// Pending migrations are available from the Provider but have not been
+	// applied yet.
+	Pending MigrationState = iota
+	// Applied migrations have been applied and are still available from the
+	// Provider.
+	Applied
+	// Missing migrations are recorded as applied in the database but are no
+	// longer available from the Provider.
+	Missing
+)
+
+func (s MigrationState) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Applied:
+		return "applied"
+	case Missing:
+		return "missing"
+	default:
+		return "unknown"
+	}
+}
+
+// MigrationStatus is a single entry returned by Migrator.Status.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	State     MigrationState
+}
+
+// Status returns the state of every migration known either to the Provider
+// or the Database: pending migrations that have not been applied yet,
+// applied migrations, and migrations recorded in the database that are no
+// longer available from the Provider.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	provList, err := m.prov.List(ctx)
+	if err != nil {
+		return nil, xerrors.New(ErrMigrator, "unable to list available migrations", err)
+	}
+	sort.Slice(provList, func(i, j int) bool {
+		return provList[i].Version(ctx) < provList[j].Version(ctx)
+	})
+
+	records, err := m.listApplied(ctx)
+	if err != nil {
+		return nil, xerrors.New(ErrMigrator, "unable to list applied migrations", err)
+	}
+	appliedAt := make(map[int]time.Time, len(records))
+	for _, r := range records {
+		appliedAt[r.Version] = r.AppliedAt
+	}
+
+	seen := make(map[int]bool, len(provList))
+	var statuses []MigrationStatus
+	for _, pm := range provList {
+		v := pm.Version(ctx)
+		seen[v] = true
+		st := MigrationStatus{Version: v, Name: migrationName(ctx, pm), State: Pending}
+		if at, ok := appliedAt[v]; ok {
+			at := at
+			st.AppliedAt = &at
+			st.State = Applied
+		}
+		statuses = append(statuses, st)
+	}
+	for _, r := range records {
+		if seen[r.Version] {
+			continue
+		}
+		at := r.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   r.Version,
+			AppliedAt: &at,
+			State:     Missing,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// listApplied returns the applied migrations, using TimestampedDatabase when
+// the underlying Database implements it.
+func (m *Migrator) listApplied(ctx context.Context) ([]MigrationRecord, error) {
+	if td, ok := m.db.(TimestampedDatabase); ok {
+		return td.ListWithTimestamps(ctx)
+	}
+	vs, err := m.db.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]MigrationRecord, len(vs))
+	for i, v := range vs {
+		records[i] = MigrationRecord{Version: v}
+	}
+	return records, nil
+}
+
+func migrationName(ctx context.Context, m Migration) string {
+	if nm, ok := m.(NamedMigration); ok {
+		return nm.Name(ctx)
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator_Status(t *testing.T) {
+	ctx := context.Background()
+
+	p := &providerMock{}
+	d := &databaseMock{}
+	m := New(p, d)
+
+	var migrations []Migration
+	migrations = append(migrations,
+		&testMigration{version: 1, up: "up1", down: "down1"},
+		&testMigration{version: 2, up: "up2", down: "down2"},
+		&testMigration{version: 4, up: "up4", down: "down4"},
+	)
+	p.On("List").Return(migrations, nil)
+	// Version 3 is applied but no longer provided by the provider, version
+	// 4 is provided but not yet applied.
+	d.On("List").Return([]int{1, 3}, nil)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []MigrationStatus{
+		{Version: 1, State: Applied, AppliedAt: statuses[0].AppliedAt},
+		{Version: 2, State: Pending},
+		{Version: 3, State: Missing, AppliedAt: statuses[2].AppliedAt},
+		{Version: 4, State: Pending},
+	}, statuses)
+}
+
+type timestampedDatabaseMock struct {
+	databaseMock
+}
+
+func (d *timestampedDatabaseMock) ListWithTimestamps(_ context.Context) ([]MigrationRecord, error) {
+	args := d.Called()
+	return args.Get(0).([]MigrationRecord), args.Error(1)
+}
+
+func TestMigrator_Status_UsesTimestampedDatabase(t *testing.T) {
+	ctx := context.Background()
+	appliedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	p := &providerMock{}
+	d := &timestampedDatabaseMock{}
+	m := New(p, d)
+
+	migrations := []Migration{&testMigration{version: 1, up: "up1", down: "down1"}}
+	p.On("List").Return(migrations, nil)
+	d.On("ListWithTimestamps").Return([]MigrationRecord{{Version: 1, AppliedAt: appliedAt}}, nil)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []MigrationStatus{
+		{Version: 1, State: Applied, AppliedAt: &appliedAt},
+	}, statuses)
+}